@@ -0,0 +1,106 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/keep94/securecookie"
+	"github.com/keep94/sessions"
+)
+
+// maxCookieSize is the largest Set-Cookie header CookieStore will write,
+// measured the same way Save measures it: name, value, and attributes
+// together, via (*http.Cookie).String(). RFC 6265 guarantees browsers
+// accept cookies up to 4096 bytes on that basis, so CookieStore rejects
+// anything that would not fit.
+const maxCookieSize = 4096
+
+// ErrCookieTooLarge is returned by CookieStore.Save when the session
+// Values, once signed, encrypted, and wrapped in a Set-Cookie header, no
+// longer fit in a single cookie.
+var ErrCookieTooLarge = errors.New("ramstore: encoded session exceeds 4KB cookie limit")
+
+// CookieStore is a session store for Gorilla Web Toolkit that keeps the
+// entire session inside a signed and encrypted cookie rather than on
+// the server. Unlike RAMStore, a CookieStore holds no session state of
+// its own, so it works unmodified behind a load balancer fronting
+// multiple server instances that do not share memory.
+type CookieStore struct {
+	Options *sessions.Options
+	Codecs  []securecookie.Codec
+}
+
+// NewCookieStore creates a new CookieStore. keyPairs is one or more
+// (hashKey, blockKey) pairs, as accepted by securecookie.CodecsFromPairs:
+// the first pair signs and encrypts new cookies while every pair is
+// tried in order when decoding, so old keys can be kept around to
+// rotate in a new pair without invalidating existing sessions. The
+// returned *CookieStore uses '/' as the cookie path.
+//
+// securecookie.New defaults each codec's own MaxLength to 4096 bytes of
+// encoded value alone, which would reject an oversized session with its
+// own generic error before Save's 4KB Set-Cookie check ever ran. Since
+// Save already enforces the real browser-facing limit, NewCookieStore
+// disables the codecs' built-in cap so Save's ErrCookieTooLarge is the
+// one callers actually see.
+func NewCookieStore(keyPairs ...[]byte) *CookieStore {
+	codecs := securecookie.CodecsFromPairs(keyPairs...)
+	for _, codec := range codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxLength(0)
+		}
+	}
+	return &CookieStore{
+		Options: &sessions.Options{Path: "/"},
+		Codecs:  codecs,
+	}
+}
+
+// Get retrieves the session. name is the name of the cookie storing the
+// session. If Get is called a second time with the same request
+// pointer, the session is retrieved from the request's context rather
+// than by decoding the cookie again. Callers should call
+// context.Clear() in a defer statement after calling Get.
+func (s *CookieStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New fetches the session from the cookie named name. Get calls New if
+// the session is not already cached in the request's context. New never
+// returns a non-nil error simply because the cookie is missing or fails
+// to decode; it returns a new, empty session in that case instead.
+func (s *CookieStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	defaultOptions := *s.Options
+	session.Options = &defaultOptions
+	session.IsNew = true
+	if c, errCookie := r.Cookie(name); errCookie == nil {
+		if err := securecookie.DecodeMulti(
+			name, c.Value, &session.Values, s.Codecs...); err == nil {
+			session.IsNew = false
+		}
+	}
+	return session, nil
+}
+
+// Save encodes session's Values into name's cookie and writes it to w.
+// Save returns ErrCookieTooLarge if the resulting Set-Cookie header,
+// name and attributes included, no longer fits within the 4KB a
+// browser is guaranteed to accept.
+func (s *CookieStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.Values, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	cookie := sessions.NewCookie(session.Name(), encoded, session.Options)
+	if len(cookie.String()) > maxCookieSize {
+		return ErrCookieTooLarge
+	}
+	http.SetCookie(w, cookie)
+	return nil
+}
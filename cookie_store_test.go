@@ -0,0 +1,106 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var (
+	testHashKey1  = []byte("01234567890123456789012345678901")
+	testBlockKey1 = []byte("0123456789012345")
+	testHashKey2  = []byte("10987654321098765432109876543210")
+	testBlockKey2 = []byte("1098765432109876")
+)
+
+func TestCookieStoreSaveGet(t *testing.T) {
+	request := &http.Request{}
+	s := NewCookieStore(testHashKey1, testBlockKey1)
+	session, err := s.Get(request, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	if !session.IsNew {
+		t.Error("Expected session to be new.")
+	}
+	session.Values["count"] = 3
+	w := &responseWriter{http.Header{}}
+	if err := session.Save(request, w); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	anotherRequest := &http.Request{Header: http.Header{"Cookie": w.Header()["Set-Cookie"]}}
+	session, err = s.Get(anotherRequest, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	if session.IsNew {
+		t.Error("Expected session not to be new.")
+	}
+	if output := session.Values["count"]; output != 3 {
+		t.Errorf("Expected 3, got %v", output)
+	}
+}
+
+func TestCookieStoreBadCookieIsNew(t *testing.T) {
+	cookie := "session-cookie=garbage; Path=/"
+	request := &http.Request{Header: http.Header{"Cookie": []string{cookie}}}
+	s := NewCookieStore(testHashKey1, testBlockKey1)
+	session, err := s.Get(request, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	if !session.IsNew {
+		t.Error("Expected an undecodable cookie to yield a new session.")
+	}
+}
+
+func TestCookieStoreTooLarge(t *testing.T) {
+	request := &http.Request{}
+	s := NewCookieStore(testHashKey1, testBlockKey1)
+	session, err := s.Get(request, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	session.Values["blob"] = strings.Repeat("x", maxCookieSize)
+	w := &responseWriter{http.Header{}}
+	if err := session.Save(request, w); err != ErrCookieTooLarge {
+		t.Errorf("Expected ErrCookieTooLarge, got %v", err)
+	}
+}
+
+// TestCookieStoreKeyRotation shows that prepending a new key pair for
+// encoding new cookies does not break decoding of cookies signed and
+// encrypted under the previous pair, the way rotating in a new key
+// should work.
+func TestCookieStoreKeyRotation(t *testing.T) {
+	request := &http.Request{}
+	oldStore := NewCookieStore(testHashKey1, testBlockKey1)
+	session, err := oldStore.Get(request, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	session.Values["count"] = 3
+	w := &responseWriter{http.Header{}}
+	if err := session.Save(request, w); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	rotatedStore := NewCookieStore(testHashKey2, testBlockKey2, testHashKey1, testBlockKey1)
+	anotherRequest := &http.Request{Header: http.Header{"Cookie": w.Header()["Set-Cookie"]}}
+	session, err = rotatedStore.Get(anotherRequest, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	if session.IsNew {
+		t.Error("Expected the cookie from the old key pair to still decode.")
+	}
+	if output := session.Values["count"]; output != 3 {
+		t.Errorf("Expected 3, got %v", output)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileSessionData implements SessionData by storing each session as its
+// own gob-encoded file under Dir. Saving writes to a temporary file in
+// Dir followed by an atomic rename so that a crash mid-write never
+// leaves a corrupt session file behind. Unlike RAMSessions, sessions
+// stored with FileSessionData survive process restarts.
+type FileSessionData struct {
+	Dir    string
+	MaxAge int64
+}
+
+// NewFileSessionData creates a FileSessionData rooted at dir, which must
+// already exist. maxAge is the maximum time of inactivity in seconds
+// before a session file is considered expired. NewFileSessionData starts
+// a goroutine that calls Purge periodically to remove expired session
+// files; clients need not call Purge themselves.
+func NewFileSessionData(dir string, maxAge int) *FileSessionData {
+	result := &FileSessionData{Dir: dir, MaxAge: int64(maxAge)}
+	go func() {
+		for {
+			<-time.After(time.Duration(maxAge) * time.Second)
+			result.Purge()
+		}
+	}()
+	return result
+}
+
+type fileSession struct {
+	Data         map[interface{}]interface{}
+	LastAccessed int64
+}
+
+// GetData implements SessionData.
+func (f *FileSessionData) GetData(id string) (map[interface{}]interface{}, error) {
+	session, err := f.read(id)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if nowInSeconds()-session.LastAccessed > f.MaxAge {
+		return nil, nil
+	}
+	session.LastAccessed = nowInSeconds()
+	if err := f.write(id, session); err != nil {
+		return nil, err
+	}
+	return session.Data, nil
+}
+
+// SaveData implements SessionData. SaveData makes a shallow copy of
+// values before saving.
+func (f *FileSessionData) SaveData(id string, values map[interface{}]interface{}) error {
+	return f.write(id, &fileSession{Data: copyMap(values), LastAccessed: nowInSeconds()})
+}
+
+// Purge removes session files that have already expired. Clients need
+// not call this manually; NewFileSessionData starts a goroutine that
+// calls this periodically.
+func (f *FileSessionData) Purge() error {
+	infos, err := ioutil.ReadDir(f.Dir)
+	if err != nil {
+		return err
+	}
+	now := nowInSeconds()
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		id := info.Name()
+		session, err := f.read(id)
+		if err != nil {
+			continue
+		}
+		if now-session.LastAccessed > f.MaxAge {
+			os.Remove(f.path(id))
+		}
+	}
+	return nil
+}
+
+func (f *FileSessionData) read(id string) (*fileSession, error) {
+	content, err := ioutil.ReadFile(f.path(id))
+	if err != nil {
+		return nil, err
+	}
+	var session fileSession
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (f *FileSessionData) write(id string, session *fileSession) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(session); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(f.Dir, "tmp-"+id)
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, f.path(id))
+}
+
+func (f *FileSessionData) path(id string) string {
+	return filepath.Join(f.Dir, id)
+}
+
+// init registers the "file" provider. config is of the form
+// "dir,maxAge" where dir is a directory that already exists and maxAge
+// is the maximum inactivity in seconds before a session expires.
+func init() {
+	RegisterProvider("file", func(config string) (SessionData, error) {
+		parts := strings.SplitN(config, ",", 2)
+		if len(parts) != 2 {
+			return nil, errors.New(`ramstore: file provider config must be "dir,maxAge"`)
+		}
+		maxAge, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		return NewFileSessionData(parts[0], maxAge), nil
+	})
+}
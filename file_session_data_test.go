@@ -0,0 +1,150 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// newFileSessionDataForTesting builds a FileSessionData directly,
+// bypassing NewFileSessionData, so tests do not start a background
+// Purge goroutine racing against their own direct file manipulation.
+func newFileSessionDataForTesting(dir string, maxAge int64) *FileSessionData {
+	return &FileSessionData{Dir: dir, MaxAge: maxAge}
+}
+
+func TestFileSessionDataSaveGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ramstore-file-test")
+	if err != nil {
+		t.Fatalf("Expected no error creating temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	f := newFileSessionDataForTesting(dir, 900)
+
+	if err := f.SaveData("sess1", map[interface{}]interface{}{"count": 3}); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+	data, err := f.GetData("sess1")
+	if err != nil {
+		t.Fatalf("Expected no error getting, got %v", err)
+	}
+	if output := data["count"]; output != 3 {
+		t.Errorf("Expected 3, got %v", output)
+	}
+}
+
+func TestFileSessionDataGetMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ramstore-file-test")
+	if err != nil {
+		t.Fatalf("Expected no error creating temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	f := newFileSessionDataForTesting(dir, 900)
+
+	data, err := f.GetData("missing")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if data != nil {
+		t.Errorf("Expected nil, got %v", data)
+	}
+}
+
+func TestFileSessionDataExpire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ramstore-file-test")
+	if err != nil {
+		t.Fatalf("Expected no error creating temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	f := newFileSessionDataForTesting(dir, 900)
+
+	// Write a session whose LastAccessed is already far enough in the
+	// past to have expired, rather than sleeping for real in the test.
+	stale := &fileSession{
+		Data:         map[interface{}]interface{}{"count": 3},
+		LastAccessed: nowInSeconds() - 901,
+	}
+	if err := f.write("sess1", stale); err != nil {
+		t.Fatalf("Expected no error writing, got %v", err)
+	}
+	data, err := f.GetData("sess1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if data != nil {
+		t.Errorf("Expected nil for an expired session, got %v", data)
+	}
+}
+
+func TestFileSessionDataPurgeRemovesExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ramstore-file-test")
+	if err != nil {
+		t.Fatalf("Expected no error creating temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	f := newFileSessionDataForTesting(dir, 900)
+
+	if err := f.write("fresh", &fileSession{
+		Data:         map[interface{}]interface{}{"count": 1},
+		LastAccessed: nowInSeconds(),
+	}); err != nil {
+		t.Fatalf("Expected no error writing, got %v", err)
+	}
+	if err := f.write("stale", &fileSession{
+		Data:         map[interface{}]interface{}{"count": 2},
+		LastAccessed: nowInSeconds() - 901,
+	}); err != nil {
+		t.Fatalf("Expected no error writing, got %v", err)
+	}
+
+	if err := f.Purge(); err != nil {
+		t.Fatalf("Expected no error purging, got %v", err)
+	}
+	if _, err := os.Stat(f.path("stale")); !os.IsNotExist(err) {
+		t.Errorf("Expected stale session file to be removed, stat err = %v", err)
+	}
+	if _, err := os.Stat(f.path("fresh")); err != nil {
+		t.Errorf("Expected fresh session file to remain, got %v", err)
+	}
+}
+
+// TestFileSessionDataIgnoresStaleTmpFile confirms that write's
+// temp-file-then-rename sequence keeps a leftover temp file, such as
+// one a crash might leave behind mid-write, from ever being read back:
+// GetData always reads the path a completed rename would have produced,
+// never a tmp-* file.
+func TestFileSessionDataIgnoresStaleTmpFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ramstore-file-test")
+	if err != nil {
+		t.Fatalf("Expected no error creating temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	f := newFileSessionDataForTesting(dir, 900)
+
+	if err := f.SaveData("sess1", map[interface{}]interface{}{"count": 3}); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	// Simulate a crash between writing the temp file and renaming it
+	// over the real session file: leave a stray, incomplete temp file
+	// in Dir for the same id.
+	tmp, err := ioutil.TempFile(dir, "tmp-sess1")
+	if err != nil {
+		t.Fatalf("Expected no error creating stray temp file, got %v", err)
+	}
+	tmp.WriteString("not a valid gob stream")
+	tmp.Close()
+
+	data, err := f.GetData("sess1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if output := data["count"]; output != 3 {
+		t.Errorf("Expected the last successful rename to win, got %v", output)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import "encoding/gob"
+
+// FlashLevel indicates the severity of a SessionFlash, similar to the
+// info/warning/error flash categories common in Rails-style web
+// frameworks.
+type FlashLevel int
+
+const (
+	FlashInfo FlashLevel = iota
+	FlashWarning
+	FlashError
+)
+
+// String returns the lower case name of level, e.g. "info".
+func (level FlashLevel) String() string {
+	switch level {
+	case FlashInfo:
+		return "info"
+	case FlashWarning:
+		return "warning"
+	case FlashError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// SessionFlash pairs a flash message with a severity Level so that
+// templates can style flashes from different levels differently.
+// *sessions.Session already supports flash messages through AddFlash and
+// Flashes, storing them under the "_flash" key; SessionFlash is simply a
+// convenience value to put in those flashes. Values stored with
+// session.AddFlash survive RAMStore.Save and RAMStore's load of a
+// session the same way any other session.Values entry does, and
+// session.Flashes consumes them so they are not returned again on a
+// later request.
+type SessionFlash struct {
+	Level   FlashLevel
+	Message string
+}
+
+func init() {
+	gob.Register(SessionFlash{})
+}
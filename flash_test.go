@@ -0,0 +1,67 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestFlashRoundTrip shows a handler adding a flash on one request and a
+// later request popping it, as would happen across a redirect. It
+// exercises AddFlash/Flashes through RAMStore.save/load to confirm
+// flashes, stored under the "_flash" key, survive the shallow copy
+// RAMSessions makes of session.Values.
+func TestFlashRoundTrip(t *testing.T) {
+	s := NewRAMStore(900)
+
+	// First request: a handler adds a flash and saves the session,
+	// for example right before issuing a redirect.
+	request1 := &http.Request{}
+	session, err := s.Get(request1, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	session.AddFlash(SessionFlash{Level: FlashInfo, Message: "Saved"})
+	w1 := &responseWriter{http.Header{}}
+	if err := session.Save(request1, w1); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	// Second request: the redirect target pops the flash and renders
+	// it, then saves the session again so it is gone for good.
+	request2 := &http.Request{Header: http.Header{"Cookie": w1.Header()["Set-Cookie"]}}
+	session, err = s.Get(request2, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	flashes := session.Flashes()
+	if len(flashes) != 1 {
+		t.Fatalf("Expected 1 flash, got %d", len(flashes))
+	}
+	flash, ok := flashes[0].(SessionFlash)
+	if !ok {
+		t.Fatalf("Expected a SessionFlash, got %T", flashes[0])
+	}
+	if flash.Level != FlashInfo || flash.Message != "Saved" {
+		t.Errorf("Expected {FlashInfo Saved}, got %v", flash)
+	}
+	w2 := &responseWriter{http.Header{}}
+	if err := session.Save(request2, w2); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	// Third request: the flash was already popped, so it must not
+	// reappear.
+	request3 := &http.Request{Header: http.Header{"Cookie": w2.Header()["Set-Cookie"]}}
+	session, err = s.Get(request3, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	if flashes := session.Flashes(); len(flashes) != 0 {
+		t.Errorf("Expected no flashes, got %v", flashes)
+	}
+}
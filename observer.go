@@ -0,0 +1,50 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+// Observer lets callers plug in metrics or logging for a RAMSessions
+// instance without forking it, for instance to feed Prometheus
+// counters, structured logs, or a session-count gauge. Set the
+// Observer field on a RAMSessions to receive callbacks. Methods are
+// invoked outside of any RAMSessions lock, so a slow or blocking
+// Observer implementation only delays its own caller, never other
+// goroutines using the same RAMSessions.
+type Observer interface {
+	// OnCreate is called when Save stores data under a session ID that
+	// did not already have live data.
+	OnCreate(id string)
+	// OnAccess is called when Save overwrites an existing session's
+	// data, or when Get or Poll finds live, unexpired data.
+	OnAccess(id string)
+	// OnExpire is called once for each session ID that Get, Poll, or
+	// Purge finds to have expired from inactivity.
+	OnExpire(id string)
+	// OnPurge is called once per call to Purge with the number of
+	// sessions that call removed, possibly zero.
+	OnPurge(count int)
+}
+
+// Stats summarizes a RAMSessions instance's activity since it was
+// created. Size is the current number of stored sessions, including
+// expired ones that Purge has not yet removed. Expired is the cumulative
+// number of times a session was noticed to have expired, whether by
+// Purge or by Get/Poll encountering a stale session; the same session
+// can be counted more than once. Purges is the number of times Purge
+// has run.
+type Stats struct {
+	Size    int
+	Expired int64
+	Purges  int64
+}
+
+// Stats returns a snapshot of r's current size and cumulative activity.
+func (r *RAMSessions) Stats() Stats {
+	return Stats{
+		Size:    r.size(),
+		Expired: r.expired.Load(),
+		Purges:  r.purges.Load(),
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"sync"
+	"testing"
+)
+
+type countingObserver struct {
+	mutex    sync.Mutex
+	created  int
+	accessed int
+	expired  int
+	purged   int
+}
+
+func (o *countingObserver) OnCreate(id string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.created++
+}
+
+func (o *countingObserver) OnAccess(id string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.accessed++
+}
+
+func (o *countingObserver) OnExpire(id string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.expired++
+}
+
+func (o *countingObserver) OnPurge(count int) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.purged += count
+}
+
+func TestObserverAndStats(t *testing.T) {
+	c := newFakeClock()
+	r := newRAMSessionsForTesting(900, c.NowFunc())
+	obs := &countingObserver{}
+	r.Observer = obs
+
+	r.Save("key", map[interface{}]interface{}{5: 8})
+	r.Save("key", map[interface{}]interface{}{5: 9})
+	r.Get("key")
+	c.Wait(901)
+	r.Get("key")
+	r.Purge()
+
+	if obs.created != 1 {
+		t.Errorf("Expected 1 create, got %d", obs.created)
+	}
+	if obs.accessed != 2 {
+		t.Errorf("Expected 2 accesses, got %d", obs.accessed)
+	}
+	// "key" is noticed expired twice: once when Get finds it stale, and
+	// again when Purge later removes it, since Get does not itself
+	// delete expired entries from the map.
+	if obs.expired != 2 {
+		t.Errorf("Expected 2 expires, got %d", obs.expired)
+	}
+	if obs.purged != 1 {
+		t.Errorf("Expected 1 purged, got %d", obs.purged)
+	}
+
+	stats := r.Stats()
+	if stats.Size != 0 {
+		t.Errorf("Expected size 0, got %d", stats.Size)
+	}
+	if stats.Expired != 2 {
+		t.Errorf("Expected 2 expired, got %d", stats.Expired)
+	}
+	if stats.Purges != 1 {
+		t.Errorf("Expected 1 purge, got %d", stats.Purges)
+	}
+}
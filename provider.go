@@ -0,0 +1,73 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	"github.com/keep94/sessions"
+)
+
+// Provider creates a SessionData instance from a provider-specific
+// configuration string. Packages implementing a new persistent backend
+// call RegisterProvider with a Provider from their init function so that
+// NewStore can look them up by name.
+type Provider func(config string) (SessionData, error)
+
+var providers = make(map[string]Provider)
+
+// RegisterProvider makes a session data provider available under name so
+// that NewStore(name, config) can create a SessionData with it.
+// RegisterProvider is meant to be called from the init function of a
+// package implementing a provider. It panics if called twice with the
+// same name.
+func RegisterProvider(name string, provider Provider) {
+	if _, ok := providers[name]; ok {
+		panic("ramstore: RegisterProvider called twice for provider " + name)
+	}
+	providers[name] = provider
+}
+
+// NewStore creates a new *RAMStore whose SData field is backed by the
+// provider registered under providerName (for instance "file" or
+// "redis"). config is provider-specific; see the documentation of each
+// provider for its format. The returned *RAMStore uses '/' as the
+// cookie path like NewRAMStore.
+func NewStore(providerName, config string) (*RAMStore, error) {
+	provider, ok := providers[providerName]
+	if !ok {
+		return nil, fmt.Errorf("ramstore: unknown provider %q", providerName)
+	}
+	data, err := provider(config)
+	if err != nil {
+		return nil, err
+	}
+	return &RAMStore{
+		Options: &sessions.Options{Path: "/"},
+		SData:   data,
+	}, nil
+}
+
+// Register makes gob aware of a concrete type that clients store in
+// session Values. Providers that serialize sessions, such as the file
+// and redis providers, rely on gob to encode and decode Values, and gob
+// must know about any concrete type stored behind an interface{} before
+// it can do so. Clients should call Register once at program
+// initialization for every such type they use beyond the ones this
+// package already registers.
+func Register(value interface{}) {
+	gob.Register(value)
+}
+
+func init() {
+	gob.Register("")
+	gob.Register(0)
+	gob.Register(int64(0))
+	gob.Register(float64(0))
+	gob.Register(false)
+	gob.Register([]byte(nil))
+}
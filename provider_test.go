@@ -0,0 +1,95 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestRegisterProviderPanicsOnDuplicate(t *testing.T) {
+	RegisterProvider("provider-test-dup", func(config string) (SessionData, error) {
+		return nil, nil
+	})
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected RegisterProvider to panic on a duplicate name")
+		}
+	}()
+	RegisterProvider("provider-test-dup", func(config string) (SessionData, error) {
+		return nil, nil
+	})
+}
+
+func TestNewStoreUnknownProvider(t *testing.T) {
+	if _, err := NewStore("provider-test-no-such-provider", ""); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
+
+func TestNewStoreFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ramstore-provider-test")
+	if err != nil {
+		t.Fatalf("Expected no error creating temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := NewStore("file", dir); err == nil {
+		t.Error(`Expected an error for config missing ",maxAge"`)
+	}
+	if _, err := NewStore("file", dir+",notanumber"); err == nil {
+		t.Error("Expected an error for a non-numeric maxAge")
+	}
+
+	s, err := NewStore("file", dir+",900")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f, ok := s.SData.(*FileSessionData)
+	if !ok {
+		t.Fatalf("Expected *FileSessionData, got %T", s.SData)
+	}
+	if f.Dir != dir {
+		t.Errorf("Expected Dir %q, got %q", dir, f.Dir)
+	}
+	if f.MaxAge != 900 {
+		t.Errorf("Expected MaxAge 900, got %v", f.MaxAge)
+	}
+}
+
+func TestNewStoreRedisProvider(t *testing.T) {
+	if _, err := NewStore("redis", "localhost:6379"); err == nil {
+		t.Error(`Expected an error for config missing ",maxAge"`)
+	}
+	if _, err := NewStore("redis", "localhost:6379,notanumber"); err == nil {
+		t.Error("Expected an error for a non-numeric maxAge")
+	}
+
+	s, err := NewStore("redis", "localhost:6379,900")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rs, ok := s.SData.(*RedisSessionData)
+	if !ok {
+		t.Fatalf("Expected *RedisSessionData, got %T", s.SData)
+	}
+	if rs.MaxAge != 900 {
+		t.Errorf("Expected MaxAge 900, got %v", rs.MaxAge)
+	}
+	if rs.Prefix != "" {
+		t.Errorf("Expected empty Prefix, got %q", rs.Prefix)
+	}
+
+	s, err = NewStore("redis", "localhost:6379,900,sess:")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rs = s.SData.(*RedisSessionData)
+	if rs.Prefix != "sess:" {
+		t.Errorf("Expected Prefix %q, got %q", "sess:", rs.Prefix)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// RedisSessionData implements SessionData by storing gob-encoded session
+// values in Redis under SET with an EX equal to MaxAge. Because Redis
+// expires keys on its own, RedisSessionData needs no Purge method or GC
+// goroutine the way FileSessionData does.
+type RedisSessionData struct {
+	Pool   *redis.Pool
+	MaxAge int64
+	Prefix string
+}
+
+// NewRedisSessionData creates a RedisSessionData that stores sessions in
+// the Redis instance reachable through pool, under keys prefixed with
+// prefix. maxAge is the maximum time of inactivity in seconds before
+// Redis expires the key for a session.
+func NewRedisSessionData(pool *redis.Pool, maxAge int, prefix string) *RedisSessionData {
+	return &RedisSessionData{Pool: pool, MaxAge: int64(maxAge), Prefix: prefix}
+}
+
+// GetData implements SessionData. GetData uses GETEX rather than GET so
+// that reading a session also refreshes its TTL in Redis; otherwise an
+// actively browsing but read-only session would hard-expire maxAge
+// after its last write instead of its last access, unlike
+// FileSessionData, which always rewrites LastAccessed on read.
+func (rs *RedisSessionData) GetData(id string) (map[interface{}]interface{}, error) {
+	conn := rs.Pool.Get()
+	defer conn.Close()
+	content, err := redis.Bytes(conn.Do("GETEX", rs.key(id), "EX", rs.MaxAge))
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var data map[interface{}]interface{}
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// SaveData implements SessionData. SaveData makes a shallow copy of
+// values before saving.
+func (rs *RedisSessionData) SaveData(id string, values map[interface{}]interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(copyMap(values)); err != nil {
+		return err
+	}
+	conn := rs.Pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("SET", rs.key(id), buf.Bytes(), "EX", rs.MaxAge)
+	return err
+}
+
+func (rs *RedisSessionData) key(id string) string {
+	return rs.Prefix + id
+}
+
+// init registers the "redis" provider. config is of the form
+// "addr,maxAge[,prefix]" where addr is the "host:port" of the Redis
+// instance, maxAge is the maximum inactivity in seconds before Redis
+// expires a session key, and prefix, if given, is prepended to every
+// key ramstore writes so it can share a Redis instance with other data.
+func init() {
+	RegisterProvider("redis", func(config string) (SessionData, error) {
+		parts := strings.SplitN(config, ",", 3)
+		if len(parts) < 2 {
+			return nil, errors.New(`ramstore: redis provider config must be "addr,maxAge[,prefix]"`)
+		}
+		maxAge, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		prefix := ""
+		if len(parts) == 3 {
+			prefix = parts[2]
+		}
+		addr := parts[0]
+		pool := &redis.Pool{
+			Dial: func() (redis.Conn, error) {
+				return redis.Dial("tcp", addr)
+			},
+		}
+		return NewRedisSessionData(pool, maxAge, prefix), nil
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2013 Travis Keep. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or
+// at http://opensource.org/licenses/BSD-3-Clause.
+
+package ramstore
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	c := newFakeClock()
+	r := newRAMSessionsForTesting(900, c.NowFunc())
+	r.Save("old", map[interface{}]interface{}{5: 8})
+	c.Wait(1)
+	if !r.Rename("old", "new") {
+		t.Fatal("Expected Rename to report success")
+	}
+	if output := r.Get("old"); output != nil {
+		t.Errorf("Expected old id to be gone, got %v", output)
+	}
+	if output := r.Get("new")[5].(int); output != 8 {
+		t.Errorf("Expected 8, got %v", output)
+	}
+	if r.Rename("old", "another") {
+		t.Error("Expected Rename of a missing id to report failure")
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	request := &http.Request{}
+	s := NewRAMStore(900)
+	session, err := s.Get(request, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	session.Values["count"] = 3
+	w := &responseWriter{http.Header{}}
+	if err := session.Save(request, w); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+	oldID := session.ID
+
+	// Regenerate writes its own Set-Cookie header; use a fresh
+	// responseWriter so w2 holds only the new cookie instead of both
+	// the original and the regenerated one.
+	w2 := &responseWriter{http.Header{}}
+	if err := s.Regenerate(request, w2, session); err != nil {
+		t.Fatalf("Expected no error regenerating, got %v", err)
+	}
+	if session.ID == oldID {
+		t.Error("Expected Regenerate to assign a new session ID")
+	}
+	if output := s.Data.Get(oldID); output != nil {
+		t.Errorf("Expected old id to be gone, got %v", output)
+	}
+
+	anotherRequest := &http.Request{Header: http.Header{"Cookie": w2.Header()["Set-Cookie"]}}
+	session, err = s.Get(anotherRequest, "session-cookie")
+	if err != nil {
+		t.Fatalf("Expected no error getting session, got %v", err)
+	}
+	if session.IsNew {
+		t.Error("Expected session not to be new.")
+	}
+	if output := session.Values["count"]; output != 3 {
+		t.Errorf("Expected 3, got %v", output)
+	}
+}
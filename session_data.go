@@ -6,23 +6,52 @@
 package ramstore
 
 import (
+	"hash/fnv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// shardCount is the number of shards RAMSessions splits its data across.
+// It must be a power of two so that shardFor can select a shard with a
+// bit mask instead of a modulo. Splitting into shards lets unrelated
+// sessions be read and written concurrently instead of serializing
+// every Get/Save/Poll behind one lock.
+const shardCount = 16
+
+// accessSlackSeconds bounds how often Get refreshes a session's
+// lastAccessed. An access that lands within this many seconds of the
+// last refresh skips the atomic store, since Expired only needs
+// lastAccessed accurate to within maxAge, not to the second. This lets
+// repeated Get calls in a busy session stay on the read path.
+const accessSlackSeconds = 1
+
 // RAMSessions stores session data. Session data for a particular session
 // expires after a set time of inactivity for that session. RAMSessions can
 // be safely used with multiple goroutines. Clients should not use this type
 // directly, but should use RAMStore instead.
 type RAMSessions struct {
-	// In addition to the fields of this struct, mutex protects the contents of
-	// the data map as well as the fields of each ramSession struct, but it
-	// does not protect the contents of the map in each ramSession struct.
-	// Therefore, goroutines must treat the contents of these maps as frozen.
-	mutex  sync.Mutex
-	data   map[string]*ramSession
-	clock  func() int64
-	maxAge int64
+	// Observer, if non-nil, is notified of session lifecycle events.
+	// See the Observer documentation for when each method is called.
+	Observer Observer
+
+	shards  [shardCount]*shard
+	clock   func() int64
+	maxAge  int64
+	expired atomic.Int64
+	purges  atomic.Int64
+}
+
+// shard holds a slice of the overall session data behind its own lock so
+// that operations on sessions in different shards never contend with
+// each other. mutex protects the contents of the data map, but, as with
+// the unsharded implementation, the contents of the map in each
+// ramSession remain frozen once set; only ramSession.lastAccessed may
+// change after a session is stored, and that happens through the atomic
+// field below rather than under mutex.
+type shard struct {
+	mutex sync.RWMutex
+	data  map[string]*ramSession
 }
 
 // NewRAMSessions creates a new RAMSessions instance. maxAge is the maximum
@@ -32,10 +61,10 @@ func NewRAMSessions(maxAge int) *RAMSessions {
 }
 
 func newRAMSessionsForTesting(maxAge int, clock func() int64) *RAMSessions {
-	result := &RAMSessions{
-		data:   make(map[string]*ramSession),
-		clock:  clock,
-		maxAge: int64(maxAge)}
+	result := &RAMSessions{clock: clock, maxAge: int64(maxAge)}
+	for i := range result.shards {
+		result.shards[i] = &shard{data: make(map[string]*ramSession)}
+	}
 	go func() {
 		for {
 			<-time.After(time.Duration(maxAge) * time.Second)
@@ -74,10 +103,21 @@ func (r *RAMSessions) Poll(id string) map[interface{}]interface{} {
 // Save saves new session data for a particular session ID.
 // Save makes a shallow copy of data before saving it.
 func (r *RAMSessions) Save(id string, data map[interface{}]interface{}) {
-	data = copyMap(data)
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-	r.data[id] = &ramSession{data, r.clock()}
+	now := r.clock()
+	rs := newRAMSession(copyMap(data), now)
+	sh := r.shardFor(id)
+	sh.mutex.Lock()
+	old, existed := sh.data[id]
+	existed = existed && !old.Expired(now, r.maxAge)
+	sh.data[id] = rs
+	sh.mutex.Unlock()
+	if r.Observer != nil {
+		if existed {
+			r.Observer.OnAccess(id)
+		} else {
+			r.Observer.OnCreate(id)
+		}
+	}
 }
 
 // SaveData calls Save(id, data) and returns nil.
@@ -88,15 +128,29 @@ func (r *RAMSessions) SaveData(
 }
 
 // Purge removes session data that has already expired. Clients need not call
-// this manually as a separate go routine calls this periodically.
+// this manually as a separate go routine calls this periodically. Purge
+// walks each shard independently under that shard's own lock, so a long
+// running GC pass never blocks Get/Save/Poll calls against other shards.
 func (r *RAMSessions) Purge() {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
 	now := r.clock()
-	for k, v := range r.data {
-		if v.Expired(now, r.maxAge) {
-			delete(r.data, k)
+	var expiredIDs []string
+	for _, sh := range r.shards {
+		sh.mutex.Lock()
+		for k, v := range sh.data {
+			if v.Expired(now, r.maxAge) {
+				delete(sh.data, k)
+				expiredIDs = append(expiredIDs, k)
+			}
 		}
+		sh.mutex.Unlock()
+	}
+	r.purges.Add(1)
+	r.expired.Add(int64(len(expiredIDs)))
+	if r.Observer != nil {
+		for _, id := range expiredIDs {
+			r.Observer.OnExpire(id)
+		}
+		r.Observer.OnPurge(len(expiredIDs))
 	}
 }
 
@@ -108,41 +162,120 @@ func (r *RAMSessions) AsPoller() SessionData {
 
 func (r *RAMSessions) get(
 	id string, updateLastAccessed bool) map[interface{}]interface{} {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-	ramSession := r.data[id]
-	if ramSession == nil {
+	sh := r.shardFor(id)
+	sh.mutex.RLock()
+	rs := sh.data[id]
+	sh.mutex.RUnlock()
+	if rs == nil {
 		return nil
 	}
-	return ramSession.Get(r.clock(), r.maxAge, updateLastAccessed)
+	now := r.clock()
+	if rs.Expired(now, r.maxAge) {
+		r.expired.Add(1)
+		if r.Observer != nil {
+			r.Observer.OnExpire(id)
+		}
+		return nil
+	}
+	if updateLastAccessed {
+		rs.touch(now)
+	}
+	if r.Observer != nil {
+		r.Observer.OnAccess(id)
+	}
+	return rs.data
 }
 
-func (r *RAMSessions) lenForTesting() int {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-	return len(r.data)
+func (r *RAMSessions) shardFor(id string) *shard {
+	return r.shards[r.shardIndex(id)]
+}
+
+func (r *RAMSessions) shardIndex(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() & (shardCount - 1))
+}
+
+// Rename atomically moves the live, unexpired data stored under oldID
+// so that it is instead stored under newID, preserving both the data
+// itself and lastAccessed. Rename reports whether oldID had live data
+// to move; if not, for instance because oldID was never saved or has
+// already expired, Rename leaves newID untouched and returns false.
+//
+// RAMStore.Regenerate uses Rename to rotate a session's ID after a
+// login boundary without resetting its inactivity timer, which is the
+// standard defense against session fixation attacks. Rename holds the
+// shards for oldID and newID locked across the delete and insert, in a
+// fixed shard-index order, so a concurrent Get or Save can never
+// observe oldID missing and newID not yet set, and two concurrent
+// Renames can never deadlock against each other.
+func (r *RAMSessions) Rename(oldID, newID string) bool {
+	oldIdx, newIdx := r.shardIndex(oldID), r.shardIndex(newID)
+	if oldIdx == newIdx {
+		sh := r.shards[oldIdx]
+		sh.mutex.Lock()
+		defer sh.mutex.Unlock()
+		return r.moveLocked(sh, oldID, sh, newID)
+	}
+	first, second := oldIdx, newIdx
+	if first > second {
+		first, second = second, first
+	}
+	r.shards[first].mutex.Lock()
+	defer r.shards[first].mutex.Unlock()
+	r.shards[second].mutex.Lock()
+	defer r.shards[second].mutex.Unlock()
+	return r.moveLocked(r.shards[oldIdx], oldID, r.shards[newIdx], newID)
+}
+
+// moveLocked moves oldID's data from oldShard to newID in newShard.
+// Callers must already hold whatever locks are needed to make the move
+// safe; moveLocked itself takes no locks.
+func (r *RAMSessions) moveLocked(oldShard *shard, oldID string, newShard *shard, newID string) bool {
+	rs, ok := oldShard.data[oldID]
+	if !ok || rs.Expired(r.clock(), r.maxAge) {
+		return false
+	}
+	delete(oldShard.data, oldID)
+	newShard.data[newID] = rs
+	return true
+}
+
+// size returns the current number of entries across all shards,
+// including expired entries that Purge has not yet removed.
+// It backs both Stats and the test suite's size assertions.
+func (r *RAMSessions) size() int {
+	total := 0
+	for _, sh := range r.shards {
+		sh.mutex.RLock()
+		total += len(sh.data)
+		sh.mutex.RUnlock()
+	}
+	return total
 }
 
 type ramSession struct {
 	data         map[interface{}]interface{}
-	lastAccessed int64
+	lastAccessed atomic.Int64
 }
 
-func (r *ramSession) Get(
-	now int64,
-	maxAge int64,
-	updateLastAccessed bool) map[interface{}]interface{} {
-	if r.Expired(now, maxAge) {
-		return nil
-	}
-	if updateLastAccessed {
-		r.lastAccessed = now
+func newRAMSession(data map[interface{}]interface{}, now int64) *ramSession {
+	rs := &ramSession{data: data}
+	rs.lastAccessed.Store(now)
+	return rs
+}
+
+// touch refreshes lastAccessed to now, unless the last refresh happened
+// within accessSlackSeconds, in which case it does nothing so that
+// callers reading on the hot path need not take a write lock.
+func (r *ramSession) touch(now int64) {
+	if now-r.lastAccessed.Load() >= accessSlackSeconds {
+		r.lastAccessed.Store(now)
 	}
-	return r.data
 }
 
 func (r *ramSession) Expired(now int64, maxAge int64) bool {
-	return now-r.lastAccessed > maxAge
+	return now-r.lastAccessed.Load() > maxAge
 }
 
 type poller struct {
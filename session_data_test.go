@@ -80,12 +80,12 @@ func TestPurge(t *testing.T) {
   c.Wait(1)
   r.Save("key3", nil)
   r.Purge()
-  if output := r.lenForTesting(); output != 3 {
+  if output := r.size(); output != 3 {
     t.Errorf("Expected 3, got %v", output)
   }
   c.Wait(900)
   r.Purge()
-  if output := r.lenForTesting(); output != 1 {
+  if output := r.size(); output != 1 {
     t.Errorf("Expected 1, got %v", output)
   }
 }
@@ -26,6 +26,15 @@ type SessionData interface {
 	SaveData(id string, values map[interface{}]interface{}) error
 }
 
+// Renamer is implemented by SessionData implementations, such as
+// RAMSessions, that can atomically move a session's data from one ID to
+// another. RAMStore.Regenerate uses Rename when the store's SessionData
+// implements Renamer so that rotating a session's ID need not reset the
+// inactivity timer the way copying Values to a new ID and saving would.
+type Renamer interface {
+	Rename(oldID, newID string) bool
+}
+
 // RAMStore is an in-memory session store for Gorilla Web Toolkit. This store
 // makes shallow copies of maps, so value objects such as string and int can be
 // safely used with in-memory sessions with no regard for synchronization.
@@ -95,6 +104,31 @@ func (s *RAMStore) Save(r *http.Request, w http.ResponseWriter, session *session
 	return nil
 }
 
+// Regenerate rotates session's ID to a newly generated random value and
+// issues a fresh cookie for it, the standard defense against session
+// fixation attacks: call it right after a client authenticates so that
+// any ID an attacker fixated on the client beforehand no longer refers
+// to the now-authenticated session. If the store's underlying
+// SessionData implements Renamer (as RAMSessions does), Regenerate uses
+// it to move the existing data to the new ID in place, preserving
+// lastAccessed; otherwise, and whenever session is new or the rename
+// finds no data to move, Regenerate falls back to saving session's
+// current Values under the new ID.
+func (s *RAMStore) Regenerate(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	oldID := session.ID
+	session.ID = strings.TrimRight(
+		base32.StdEncoding.EncodeToString(
+			securecookie.GenerateRandomKey(32)), "=")
+	renamer, canRename := s.getData().(Renamer)
+	if !canRename || oldID == "" || !renamer.Rename(oldID, session.ID) {
+		if err := s.save(session); err != nil {
+			return err
+		}
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), session.ID, session.Options))
+	return nil
+}
+
 func (s *RAMStore) save(session *sessions.Session) error {
 	return s.getData().SaveData(session.ID, session.Values)
 }